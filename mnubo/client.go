@@ -2,7 +2,7 @@ package mnubo
 
 import (
 	"bytes"
-	gzip "compress/gzip"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -15,6 +15,9 @@ import (
 type CompressionConfig struct {
 	Request  bool
 	Response bool
+	// Level tunes CPU vs. bytes for request compression. Zero means
+	// gzip.BestSpeed, matching prior behavior.
+	Level int
 }
 
 type Mnubo struct {
@@ -24,6 +27,25 @@ type Mnubo struct {
 	Host         string
 	AccessToken  AccessToken
 	Compression  CompressionConfig
+	// RetryPolicy controls automatic retries of failed requests in doRequest.
+	// The zero value disables retrying, preserving prior behavior.
+	RetryPolicy RetryPolicy
+	// HTTPClient is the client used for every request. Set via WithHTTPClient
+	// or WithRoundTripper to customize connection pooling, mTLS, proxying, or
+	// instrumentation; defaults to a client with a 30s timeout.
+	HTTPClient *http.Client
+	// RequestHook, if set, is called immediately before each request is sent.
+	RequestHook RequestHook
+	// ResponseHook, if set, is called after each request completes.
+	ResponseHook ResponseHook
+	// MaxResponseBytes caps how much of a response body doRequest will read.
+	// Zero means defaultMaxResponseBytes.
+	MaxResponseBytes int64
+	// TokenRefreshSkew is how far ahead of expiry the access token is
+	// proactively refreshed. Zero means defaultTokenRefreshSkew.
+	TokenRefreshSkew time.Duration
+
+	tokens tokenSource
 }
 
 type ClientRequest struct {
@@ -33,6 +55,18 @@ type ClientRequest struct {
 	contentType     string
 	payload         []byte
 	skipCompression bool
+	// forceCompression gzip-encodes cr.payload (streamed via gzipPipeReader,
+	// never buffered whole) regardless of Mnubo.Compression.Request, for
+	// callers like the batch stream uploader that always want a compressed
+	// wire body.
+	forceCompression bool
+	// maxResponseBytes overrides Mnubo.MaxResponseBytes for this request; 0
+	// means "use the client default".
+	maxResponseBytes int64
+	// retryPolicy overrides Mnubo.RetryPolicy for this request, when
+	// hasRetryPolicy is set.
+	retryPolicy    RetryPolicy
+	hasRetryPolicy bool
 }
 
 type AccessToken struct {
@@ -44,24 +78,19 @@ type AccessToken struct {
 	Jti       string `json:"jti"`
 }
 
-func (at *AccessToken) hasExpired() bool {
-	now := time.Now()
-	return at.ExpiresAt.Before(now)
-}
-
-func NewClient(id string, secret string, host string) *Mnubo {
-	return &Mnubo{
+func NewClient(id string, secret string, host string, opts ...Option) *Mnubo {
+	return applyOptions(&Mnubo{
 		ClientId:     id,
 		ClientSecret: secret,
 		Host:         host,
-	}
+	}, opts)
 }
 
-func NewClientWithToken(token string, host string) *Mnubo {
-	return &Mnubo{
+func NewClientWithToken(token string, host string, opts ...Option) *Mnubo {
+	return applyOptions(&Mnubo{
 		ClientToken: token,
 		Host:        host,
-	}
+	}, opts)
 }
 
 func (m *Mnubo) isUsingStaticToken() bool {
@@ -72,7 +101,19 @@ func (m *Mnubo) GetAccessToken() (AccessToken, error) {
 	return m.GetAccessTokenWithScope("ALL")
 }
 
+// GetAccessTokenContext is the context-aware equivalent of GetAccessToken.
+func (m *Mnubo) GetAccessTokenContext(ctx context.Context) (AccessToken, error) {
+	return m.GetAccessTokenWithScopeContext(ctx, "ALL")
+}
+
 func (m *Mnubo) GetAccessTokenWithScope(scope string) (AccessToken, error) {
+	return m.GetAccessTokenWithScopeContext(context.Background(), scope)
+}
+
+// GetAccessTokenWithScopeContext is the context-aware equivalent of
+// GetAccessTokenWithScope; ctx governs the request and its retries, so
+// callers can enforce a deadline or cancel an in-flight token fetch.
+func (m *Mnubo) GetAccessTokenWithScopeContext(ctx context.Context, scope string) (AccessToken, error) {
 	payload := fmt.Sprintf("grant_type=client_credentials&scope=%s", scope)
 	data := []byte(fmt.Sprintf("%s:%s", m.ClientId, m.ClientSecret))
 
@@ -85,68 +126,125 @@ func (m *Mnubo) GetAccessTokenWithScope(scope string) (AccessToken, error) {
 		payload:         []byte(payload),
 	}
 	at := AccessToken{}
-	body, err := m.doRequest(cr)
+	body, err := m.doRequest(ctx, cr)
 	now := time.Now()
 
 	if err == nil {
 		err = json.Unmarshal(body, &at)
 		if err != nil {
-			return at, fmt.Errorf("unable to unmarshall body %t", err)
+			return at, fmt.Errorf("unable to unmarshall body: %w", err)
 		}
-		dur, err := time.ParseDuration(fmt.Sprintf("%dms", at.ExpiresIn))
+		// ExpiresIn is seconds per the OAuth2 spec (RFC 6749 §4.2.2), not milliseconds.
+		dur, err := time.ParseDuration(fmt.Sprintf("%ds", at.ExpiresIn))
 		at.ExpiresAt = now.Add(dur)
-		m.AccessToken = at
+		m.setAccessToken(at)
 		return at, err
 	}
 	return at, err
 }
 
-func doGzip(w io.Writer, data []byte) error {
-	gw, err := gzip.NewWriterLevel(w, gzip.BestSpeed)
-	if err != nil {
-		return err
-	}
-	if _, err := gw.Write(data); err != nil {
-		return err
+// doRequest sends cr, retrying according to m.RetryPolicy (or cr.retryPolicy,
+// if set) when the response status or transport error is retryable.
+// cr.payload is re-compressed on every attempt, since the wire body of a
+// gzip'd attempt can't be replayed as-is.
+func (m *Mnubo) doRequest(ctx context.Context, cr ClientRequest) ([]byte, error) {
+	policy := m.RetryPolicy
+	if cr.hasRetryPolicy {
+		policy = cr.retryPolicy
 	}
-	if err := gw.Flush(); err != nil {
-		return err
+	policy = policy.withDefaults()
+
+	var lastErr error
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := policy.delayForAttempt(attempt - 1)
+			if retryAfter, ok := lastRetryAfter(lastErr); ok {
+				delay = retryAfter
+			}
+			if err := sleepWithContext(ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+
+		body, err := m.doRequestOnce(ctx, cr)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+
+		if attempt == policy.MaxAttempts-1 || !isRetryable(err, policy) {
+			return nil, err
+		}
 	}
-	if err := gw.Close(); err != nil {
-		return err
+	return nil, lastErr
+}
+
+// requestError carries enough of the failed response for doRequest's retry
+// loop to decide whether to retry and how long to wait.
+type requestError struct {
+	statusCode    int
+	retryAfter    time.Duration
+	hasRetryAfter bool
+	err           error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+func (e *requestError) Unwrap() error { return e.err }
+
+func isRetryable(err error, policy RetryPolicy) bool {
+	reqErr, ok := err.(*requestError)
+	if !ok {
+		// Network/transport errors (no status code) are retried too.
+		return true
 	}
-	return nil
+	return policy.isRetryableStatus(reqErr.statusCode)
 }
 
-func doGunzip(w io.Writer, data []byte) error {
-	gr, err := gzip.NewReader(bytes.NewBuffer(data))
-	defer gr.Close()
-	if err != nil {
-		return err
+func lastRetryAfter(err error) (time.Duration, bool) {
+	reqErr, ok := err.(*requestError)
+	if !ok {
+		return 0, false
 	}
-	ud, err := ioutil.ReadAll(gr)
-	if err != nil {
-		return err
+	return reqErr.retryAfter, reqErr.hasRetryAfter
+}
+
+// countingReader wraps an io.Reader to tally the bytes actually read through
+// it, so doRequestOnce can report real wire bytes regardless of whether the
+// body was compressed.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Close delegates to the wrapped reader's Close, if it has one, so
+// countingReader can stand in wherever an io.ReadCloser is required.
+func (c *countingReader) Close() error {
+	if closer, ok := c.Reader.(io.Closer); ok {
+		return closer.Close()
 	}
-	w.Write(ud)
 	return nil
 }
 
-func (m *Mnubo) doRequest(cr ClientRequest) ([]byte, error) {
-	var payload []byte
+func (m *Mnubo) doRequestOnce(ctx context.Context, cr ClientRequest) ([]byte, error) {
+	var reqBody io.Reader
+	bytesOut := len(cr.payload)
+	willCompress := cr.forceCompression || (m.Compression.Request && !cr.skipCompression)
 
-	if m.Compression.Request && !cr.skipCompression {
-		var w bytes.Buffer
-		err := doGzip(&w, cr.payload)
-		if err != nil {
-			return nil, fmt.Errorf("unable to gzip request: %t", err)
-		}
-		payload = w.Bytes()
+	if willCompress {
+		reqBody = gzipPipeReader(cr.payload, m.Compression.Level)
 	} else {
-		payload = cr.payload
+		reqBody = bytes.NewReader(cr.payload)
 	}
+	wireOut := &countingReader{Reader: reqBody}
+	reqBody = wireOut
 
-	req, err := http.NewRequest(cr.method, m.Host+cr.path, bytes.NewReader(payload))
+	req, err := http.NewRequestWithContext(ctx, cr.method, m.Host+cr.path, reqBody)
 
 	req.Header.Add("Content-Type", cr.contentType)
 	req.Header.Add("X-MNUBO-SDK", "Go")
@@ -155,7 +253,7 @@ func (m *Mnubo) doRequest(cr ClientRequest) ([]byte, error) {
 		req.Header.Add("Authorization", cr.authorization)
 	}
 
-	if m.Compression.Request {
+	if willCompress {
 		req.Header.Add("Content-Encoding", "gzip")
 	}
 
@@ -167,50 +265,128 @@ func (m *Mnubo) doRequest(cr ClientRequest) ([]byte, error) {
 		return nil, err
 	}
 
-	client := &http.Client{}
-	res, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("unable to send client request: %t", err)
+	if m.RequestHook != nil {
+		m.RequestHook(cr.method, cr.path)
 	}
-	defer res.Body.Close()
 
-	var body []byte
-	body, err = ioutil.ReadAll(res.Body)
+	start := time.Now()
+	res, err := m.httpClient().Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read response body: %t", err)
+		m.fireResponseHook(cr, hookStats{status: 0, bytesOut: bytesOut, wireBytesOut: wireOut.n, compressedIn: false, dur: time.Since(start), err: err})
+		return nil, fmt.Errorf("unable to send client request: %w", err)
 	}
-	if res.Header.Get("Content-Encoding") == "gzip" {
-		var w bytes.Buffer
-		err := doGunzip(&w, body)
+	defer res.Body.Close()
 
+	limit := m.maxResponseBytes(cr)
+	wireIn := &countingReader{Reader: res.Body}
+	var bodyReader io.Reader
+	compressedIn := res.Header.Get("Content-Encoding") == "gzip"
+	if compressedIn {
+		// The cap must bound the decompressed stream, not the wire bytes: a
+		// small gzip-bombed response can expand to orders of magnitude more
+		// than the configured limit once decompressed.
+		gr, err := acquireGzipReader(wireIn)
 		if err != nil {
-			return nil, fmt.Errorf("unable to gunzip response: %t", err)
+			m.fireResponseHook(cr, hookStats{status: res.StatusCode, bytesOut: bytesOut, wireBytesOut: wireOut.n, wireBytesIn: int(wireIn.n), compressedIn: compressedIn, dur: time.Since(start), err: err})
+			return nil, fmt.Errorf("unable to gunzip response: %w", err)
 		}
+		defer releaseGzipReader(gr)
+		bodyReader = http.MaxBytesReader(nil, gr, limit)
+	} else {
+		bodyReader = http.MaxBytesReader(nil, wireIn, limit)
+	}
 
-		body = w.Bytes()
+	body, err := ioutil.ReadAll(bodyReader)
+	if err != nil {
+		if isMaxBytesError(err) {
+			err = &ErrResponseTooLarge{Limit: limit}
+		}
+		m.fireResponseHook(cr, hookStats{status: res.StatusCode, bytesOut: bytesOut, wireBytesOut: wireOut.n, wireBytesIn: int(wireIn.n), compressedIn: compressedIn, dur: time.Since(start), err: err})
+		if _, ok := err.(*ErrResponseTooLarge); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to read response body: %w", err)
 	}
+	m.fireResponseHook(cr, hookStats{status: res.StatusCode, bytesOut: bytesOut, wireBytesOut: wireOut.n, bytesIn: len(body), wireBytesIn: int(wireIn.n), compressedIn: compressedIn, dur: time.Since(start)})
 	if res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices {
 		return body, nil
 	}
 
-	return nil, fmt.Errorf("request Error: %s", body)
+	reqErr := &requestError{
+		statusCode: res.StatusCode,
+		err:        newAPIError(res, body),
+	}
+	policy := m.RetryPolicy
+	if cr.hasRetryPolicy {
+		policy = cr.retryPolicy
+	}
+	reqErr.retryAfter, reqErr.hasRetryAfter = parseRetryAfter(res.Header.Get("Retry-After"), policy.withDefaults().MaxDelay)
+	return nil, reqErr
+}
+
+func (m *Mnubo) httpClient() *http.Client {
+	if m.HTTPClient == nil {
+		return defaultHTTPClient()
+	}
+	return m.HTTPClient
+}
+
+// hookStats carries the pieces of RequestStats gathered at different points
+// of doRequestOnce, so fireResponseHook can be called without an
+// ever-growing positional argument list.
+type hookStats struct {
+	status       int
+	bytesOut     int
+	bytesIn      int
+	wireBytesOut int64
+	wireBytesIn  int
+	compressedIn bool
+	dur          time.Duration
+	err          error
+}
+
+func (m *Mnubo) fireResponseHook(cr ClientRequest, s hookStats) {
+	if m.ResponseHook == nil {
+		return
+	}
+	m.ResponseHook(RequestStats{
+		Method:        cr.method,
+		Path:          cr.path,
+		StatusCode:    s.status,
+		Duration:      s.dur,
+		BytesIn:       s.bytesIn,
+		BytesOut:      s.bytesOut,
+		WireBytesIn:   s.wireBytesIn,
+		WireBytesOut:  s.wireBytesOut,
+		CompressedOut: cr.forceCompression || (m.Compression.Request && !cr.skipCompression),
+		CompressedIn:  s.compressedIn,
+		Err:           s.err,
+	})
 }
 
-func (m *Mnubo) doRequestWithAuthentication(cr ClientRequest, response interface{}) error {
+func (m *Mnubo) doRequestWithAuthentication(ctx context.Context, cr ClientRequest, response interface{}) error {
 	if m.isUsingStaticToken() {
 		cr.authorization = fmt.Sprintf("Bearer %s", m.ClientToken)
 	} else {
-		if m.AccessToken.hasExpired() {
-			_, err := m.GetAccessToken()
-
-			if err != nil {
-				return err
-			}
+		at, err := m.refreshAccessToken(ctx)
+		if err != nil {
+			return err
 		}
-		cr.authorization = fmt.Sprintf("Bearer %s", m.AccessToken.Value)
+		cr.authorization = fmt.Sprintf("Bearer %s", at.Value)
 	}
 
-	data, err := m.doRequest(cr)
+	data, err := m.doRequest(ctx, cr)
+
+	if reqErr, ok := err.(*requestError); ok && reqErr.statusCode == http.StatusUnauthorized && !m.isUsingStaticToken() {
+		// The token may have expired server-side before our local clock
+		// caught up; force a refresh (still coalesced via tokens.do, so a
+		// burst of concurrent 401s doesn't stampede /oauth/token) and retry
+		// exactly once.
+		if at, refreshErr := m.forceRefreshAccessToken(ctx); refreshErr == nil {
+			cr.authorization = fmt.Sprintf("Bearer %s", at.Value)
+			data, err = m.doRequest(ctx, cr)
+		}
+	}
 
 	if err != nil {
 		return err
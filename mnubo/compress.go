@@ -0,0 +1,76 @@
+package mnubo
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// gzipWriterPool holds *gzip.Writer values at the default BestSpeed level,
+// the common case, to avoid a per-request allocation. Requests that ask for
+// a non-default CompressionConfig.Level bypass the pool.
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		gw, _ := gzip.NewWriterLevel(ioutil.Discard, gzip.BestSpeed)
+		return gw
+	},
+}
+
+var gzipReaderPool = sync.Pool{}
+
+func gzipLevel(level int) int {
+	if level == 0 {
+		return gzip.BestSpeed
+	}
+	return level
+}
+
+// gzipPipeReader gzip-encodes data on the fly, streaming it through an
+// io.Pipe so the caller (typically http.NewRequestWithContext) never has to
+// hold the fully compressed body in memory before the request starts.
+func gzipPipeReader(data []byte, level int) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		var gw *gzip.Writer
+		pooled := level == 0
+		if pooled {
+			gw = gzipWriterPool.Get().(*gzip.Writer)
+			gw.Reset(pw)
+			defer gzipWriterPool.Put(gw)
+		} else {
+			gw, _ = gzip.NewWriterLevel(pw, gzipLevel(level))
+		}
+
+		if _, err := gw.Write(data); err != nil {
+			gw.Close()
+			pw.CloseWithError(err)
+			return
+		}
+		if err := gw.Close(); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.Close()
+	}()
+	return pr
+}
+
+// acquireGzipReader returns a pooled *gzip.Reader reset onto r.
+func acquireGzipReader(r io.Reader) (*gzip.Reader, error) {
+	if pooled := gzipReaderPool.Get(); pooled != nil {
+		gr := pooled.(*gzip.Reader)
+		if err := gr.Reset(r); err != nil {
+			return nil, err
+		}
+		return gr, nil
+	}
+	return gzip.NewReader(r)
+}
+
+// releaseGzipReader closes and returns a *gzip.Reader obtained from
+// acquireGzipReader to the pool.
+func releaseGzipReader(gr *gzip.Reader) {
+	gr.Close()
+	gzipReaderPool.Put(gr)
+}
@@ -0,0 +1,75 @@
+package mnubo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIError represents a non-2xx response from the SmartObjects API, with the
+// error envelope parsed when the body is JSON. Use errors.As to recover one
+// from an error returned by a Mnubo method, or the IsXxx helpers below.
+type APIError struct {
+	StatusCode int
+	RequestID  string
+	Code       string
+	Message    string
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("smartobjects: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("smartobjects: %d: %s", e.StatusCode, e.Body)
+}
+
+// apiErrorEnvelope mirrors the JSON error body returned by the SmartObjects
+// API, e.g. {"errorCode":"INVALID_EVENT","message":"..."}.
+type apiErrorEnvelope struct {
+	Code    string `json:"errorCode"`
+	Message string `json:"message"`
+}
+
+func newAPIError(res *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get("X-MNUBO-REQUEST-ID"),
+		Body:       body,
+	}
+	var envelope apiErrorEnvelope
+	if json.Unmarshal(body, &envelope) == nil {
+		apiErr.Code = envelope.Code
+		apiErr.Message = envelope.Message
+	}
+	return apiErr
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == status
+	}
+	return false
+}
+
+// IsNotFound reports whether err is an *APIError for a 404 response.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is an *APIError for a 401 response.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsRateLimited reports whether err is an *APIError for a 429 response.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsBadRequest reports whether err is an *APIError for a 400 response.
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
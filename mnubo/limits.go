@@ -0,0 +1,40 @@
+package mnubo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// defaultMaxResponseBytes bounds response bodies when Mnubo.MaxResponseBytes
+// is unset, protecting the process from an accidental huge response or a
+// compromised endpoint.
+const defaultMaxResponseBytes = 32 << 20 // 32 MiB
+
+// ErrResponseTooLarge is returned when a response body exceeds the
+// configured limit, so callers can distinguish this from a transport error
+// and either raise the limit or switch to a streaming API.
+type ErrResponseTooLarge struct {
+	Limit int64
+}
+
+func (e *ErrResponseTooLarge) Error() string {
+	return fmt.Sprintf("response body exceeds %d byte limit", e.Limit)
+}
+
+func (m *Mnubo) maxResponseBytes(cr ClientRequest) int64 {
+	if cr.maxResponseBytes > 0 {
+		return cr.maxResponseBytes
+	}
+	if m.MaxResponseBytes > 0 {
+		return m.MaxResponseBytes
+	}
+	return defaultMaxResponseBytes
+}
+
+// isMaxBytesError reports whether err came from a reader capped by
+// http.MaxBytesReader exceeding its limit.
+func isMaxBytesError(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
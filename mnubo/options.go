@@ -0,0 +1,110 @@
+package mnubo
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestHook is called just before a request is sent.
+type RequestHook func(method, path string)
+
+// ResponseHook is called after a request completes (successfully or not),
+// and can be used to emit metrics/structured logs without forking the SDK.
+type ResponseHook func(stats RequestStats)
+
+// RequestStats describes the outcome of a single HTTP round trip made by
+// doRequest, for use by a ResponseHook. BytesOut/BytesIn are always the
+// uncompressed payload sizes, consistent across every call path (including
+// batch stream uploads); WireBytesOut/WireBytesIn are the bytes that
+// actually crossed the wire, so a hook can derive the gzip ratio as
+// WireBytesOut/BytesOut.
+type RequestStats struct {
+	Method        string
+	Path          string
+	StatusCode    int
+	Duration      time.Duration
+	BytesIn       int
+	BytesOut      int
+	WireBytesIn   int
+	WireBytesOut  int64
+	CompressedIn  bool
+	CompressedOut bool
+	Err           error
+}
+
+// Option configures a Mnubo client created via NewClient/NewClientWithToken.
+type Option func(*Mnubo)
+
+// WithHTTPClient overrides the *http.Client used for every request, e.g. to
+// inject a custom RoundTripper for connection pooling, mTLS, proxying, or
+// instrumentation (OpenTelemetry, Prometheus, ...).
+func WithHTTPClient(client *http.Client) Option {
+	return func(m *Mnubo) {
+		m.HTTPClient = client
+	}
+}
+
+// WithRoundTripper wraps the default *http.Client with the given
+// RoundTripper, leaving its other settings (timeout, etc.) untouched.
+func WithRoundTripper(rt http.RoundTripper) Option {
+	return func(m *Mnubo) {
+		if m.HTTPClient == nil {
+			m.HTTPClient = defaultHTTPClient()
+		}
+		m.HTTPClient.Transport = rt
+	}
+}
+
+// WithRequestHook registers a callback fired immediately before each request
+// is sent.
+func WithRequestHook(hook RequestHook) Option {
+	return func(m *Mnubo) {
+		m.RequestHook = hook
+	}
+}
+
+// WithResponseHook registers a callback fired after each request completes.
+func WithResponseHook(hook ResponseHook) Option {
+	return func(m *Mnubo) {
+		m.ResponseHook = hook
+	}
+}
+
+// WithRetryPolicy sets the client-wide RetryPolicy applied by doRequest.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(m *Mnubo) {
+		m.RetryPolicy = policy
+	}
+}
+
+// WithMaxResponseBytes caps how much of a response body doRequest will read,
+// returning *ErrResponseTooLarge if a response exceeds it.
+func WithMaxResponseBytes(n int64) Option {
+	return func(m *Mnubo) {
+		m.MaxResponseBytes = n
+	}
+}
+
+// WithTokenRefreshSkew sets how far ahead of expiry the access token is
+// proactively refreshed.
+func WithTokenRefreshSkew(skew time.Duration) Option {
+	return func(m *Mnubo) {
+		m.TokenRefreshSkew = skew
+	}
+}
+
+func defaultHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+	}
+}
+
+func applyOptions(m *Mnubo, opts []Option) *Mnubo {
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.HTTPClient == nil {
+		m.HTTPClient = defaultHTTPClient()
+	}
+	return m
+}
@@ -0,0 +1,129 @@
+package mnubo
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how doRequest retries idempotent requests that fail
+// with a retryable status code or a network error.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// A value <= 1 disables retrying.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed delay, including any Retry-After value.
+	MaxDelay time.Duration
+	// Multiplier is applied to BaseDelay after each attempt (exponential backoff).
+	Multiplier float64
+	// Jitter is the fraction (0-1) of random jitter applied to each delay.
+	Jitter float64
+	// RetryableStatuses lists the HTTP status codes that should be retried.
+	RetryableStatuses []int
+}
+
+// DefaultRetryPolicy is a sensible default: 4 attempts, 500ms base delay
+// doubling up to 10s, with 20% jitter, retrying 429, 500, 502, 503 and 504
+// responses.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:       4,
+	BaseDelay:         500 * time.Millisecond,
+	MaxDelay:          10 * time.Second,
+	Multiplier:        2,
+	Jitter:            0.2,
+	RetryableStatuses: []int{http.StatusTooManyRequests, 500, 502, 503, 504},
+}
+
+// isZero reports whether p is the zero-value RetryPolicy, used by callers
+// that want to distinguish "not configured" from an explicit policy.
+func (p RetryPolicy) isZero() bool {
+	return reflect.DeepEqual(p, RetryPolicy{})
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 1
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	if p.Multiplier <= 0 {
+		p.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	if p.RetryableStatuses == nil {
+		p.RetryableStatuses = DefaultRetryPolicy.RetryableStatuses
+	}
+	return p
+}
+
+func (p RetryPolicy) isRetryableStatus(status int) bool {
+	for _, s := range p.RetryableStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// delayForAttempt computes the exponential backoff delay for the given
+// (zero-indexed) attempt, with jitter applied, capped at MaxDelay.
+func (p RetryPolicy) delayForAttempt(attempt int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(p.Multiplier, float64(attempt))
+	if p.Jitter > 0 {
+		delay = delay * (1 - p.Jitter + 2*p.Jitter*rand.Float64())
+	}
+	d := time.Duration(delay)
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// parseRetryAfter parses the Retry-After header, which per RFC 7231 may be
+// either a number of delta-seconds or an HTTP-date, and clamps the result to
+// max.
+func parseRetryAfter(header string, max time.Duration) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		d := time.Duration(secs) * time.Second
+		if d > max {
+			d = max
+		}
+		return d, true
+	}
+	if when, err := http.ParseTime(header); err == nil {
+		d := time.Until(when)
+		if d < 0 {
+			d = 0
+		}
+		if d > max {
+			d = max
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleepWithContext sleeps for d, returning early with ctx.Err() if ctx is
+// cancelled first.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,104 @@
+package mnubo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDelayForAttemptCapsAtMaxDelay(t *testing.T) {
+	p := RetryPolicy{
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   1 * time.Second,
+		Multiplier: 10,
+		Jitter:     0,
+	}
+
+	d := p.delayForAttempt(5)
+	if d != p.MaxDelay {
+		t.Fatalf("delayForAttempt(5) = %v, want %v (capped)", d, p.MaxDelay)
+	}
+}
+
+func TestDelayForAttemptGrowsExponentially(t *testing.T) {
+	p := RetryPolicy{
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   10 * time.Second,
+		Multiplier: 2,
+		Jitter:     0,
+	}
+
+	first := p.delayForAttempt(0)
+	second := p.delayForAttempt(1)
+	if first != 100*time.Millisecond {
+		t.Fatalf("delayForAttempt(0) = %v, want 100ms", first)
+	}
+	if second != 200*time.Millisecond {
+		t.Fatalf("delayForAttempt(1) = %v, want 200ms", second)
+	}
+}
+
+func TestDelayForAttemptJitterStaysInRange(t *testing.T) {
+	p := RetryPolicy{
+		BaseDelay:  1 * time.Second,
+		MaxDelay:   10 * time.Second,
+		Multiplier: 1,
+		Jitter:     0.2,
+	}
+
+	for i := 0; i < 50; i++ {
+		d := p.delayForAttempt(0)
+		if d < 800*time.Millisecond || d > 1200*time.Millisecond {
+			t.Fatalf("delayForAttempt(0) = %v, want within [800ms, 1200ms]", d)
+		}
+	}
+}
+
+func TestParseRetryAfterDeltaSeconds(t *testing.T) {
+	d, ok := parseRetryAfter("5", time.Minute)
+	if !ok {
+		t.Fatal("parseRetryAfter(\"5\") = false, want true")
+	}
+	if d != 5*time.Second {
+		t.Fatalf("parseRetryAfter(\"5\") = %v, want 5s", d)
+	}
+}
+
+func TestParseRetryAfterHTTPDate(t *testing.T) {
+	when := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	d, ok := parseRetryAfter(when, time.Minute)
+	if !ok {
+		t.Fatal("parseRetryAfter(httpDate) = false, want true")
+	}
+	if d <= 0 || d > time.Minute {
+		t.Fatalf("parseRetryAfter(httpDate) = %v, want within (0, 1m]", d)
+	}
+}
+
+func TestParseRetryAfterClampsToMax(t *testing.T) {
+	d, ok := parseRetryAfter("3600", time.Second)
+	if !ok {
+		t.Fatal("parseRetryAfter(\"3600\") = false, want true")
+	}
+	if d != time.Second {
+		t.Fatalf("parseRetryAfter(\"3600\") = %v, want clamped to 1s", d)
+	}
+}
+
+func TestParseRetryAfterInvalidReturnsFalse(t *testing.T) {
+	if _, ok := parseRetryAfter("not-a-date", time.Minute); ok {
+		t.Fatal("parseRetryAfter(\"not-a-date\") = true, want false")
+	}
+	if _, ok := parseRetryAfter("", time.Minute); ok {
+		t.Fatal("parseRetryAfter(\"\") = true, want false")
+	}
+}
+
+func TestIsZero(t *testing.T) {
+	if !(RetryPolicy{}).isZero() {
+		t.Fatal("RetryPolicy{}.isZero() = false, want true")
+	}
+	if DefaultRetryPolicy.isZero() {
+		t.Fatal("DefaultRetryPolicy.isZero() = true, want false")
+	}
+}
@@ -0,0 +1,233 @@
+package mnubo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StreamOptions configures a chunked, bounded-concurrency ingestion stream.
+type StreamOptions struct {
+	// MaxInFlight caps the number of batches being sent to the API at once.
+	MaxInFlight int
+	// BatchSize is the max number of records per batch.
+	BatchSize int
+	// BatchBytes is the max serialized size (pre-compression) of a batch, in bytes.
+	BatchBytes int
+	// FlushInterval forces a partial batch to be sent once this long has passed
+	// since its first record arrived, whether or not more records keep arriving.
+	FlushInterval time.Duration
+	// OnInFlightChange, if set, is called every time a batch starts or finishes
+	// sending, with the number of batches currently in flight. Useful for
+	// exposing backpressure/concurrency metrics without polling.
+	OnInFlightChange func(inFlight int)
+	// Retry overrides Mnubo.RetryPolicy for batches sent by this stream. The
+	// zero value means "use the client's RetryPolicy".
+	Retry RetryPolicy
+}
+
+func (o StreamOptions) withDefaults() StreamOptions {
+	if o.MaxInFlight <= 0 {
+		o.MaxInFlight = 4
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1000
+	}
+	if o.BatchBytes <= 0 {
+		o.BatchBytes = 1 << 20 // 1 MiB
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = 5 * time.Second
+	}
+	return o
+}
+
+// StreamResult reports the outcome of a single record within a batch.
+type StreamResult struct {
+	Index int
+	Err   error
+}
+
+// recordResult mirrors the partial-success envelope returned by the
+// SmartObjects bulk endpoints for /events, /objects and /owners.
+type recordResult struct {
+	Result  string `json:"result"`
+	Id      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// SendEventsStream consumes records from in, batches them by count/byte-size/
+// flush-interval, and dispatches the batches to /events across a bounded pool
+// of worker goroutines. It returns a channel that receives one StreamResult
+// per record, in the order batches complete (not necessarily input order).
+// If ctx is done before a partial batch is dispatched, the records in that
+// batch get no StreamResult.
+func (m *Mnubo) SendEventsStream(ctx context.Context, in <-chan interface{}, opts StreamOptions) <-chan StreamResult {
+	return m.sendStream(ctx, "/events", in, opts)
+}
+
+// SendObjectsStream is the object-ingestion equivalent of SendEventsStream.
+func (m *Mnubo) SendObjectsStream(ctx context.Context, in <-chan interface{}, opts StreamOptions) <-chan StreamResult {
+	return m.sendStream(ctx, "/objects", in, opts)
+}
+
+// SendOwnersStream is the owner-ingestion equivalent of SendEventsStream.
+func (m *Mnubo) SendOwnersStream(ctx context.Context, in <-chan interface{}, opts StreamOptions) <-chan StreamResult {
+	return m.sendStream(ctx, "/owners", in, opts)
+}
+
+type recordBatch struct {
+	records []interface{}
+	base    int // index of records[0] within the overall stream, for result correlation
+}
+
+func (m *Mnubo) sendStream(ctx context.Context, path string, in <-chan interface{}, opts StreamOptions) <-chan StreamResult {
+	opts = opts.withDefaults()
+
+	batches := make(chan recordBatch)
+	results := make(chan StreamResult)
+
+	var inFlight int32
+
+	var workers sync.WaitGroup
+	workers.Add(opts.MaxInFlight)
+	for i := 0; i < opts.MaxInFlight; i++ {
+		go func() {
+			defer workers.Done()
+			for batch := range batches {
+				n := atomic.AddInt32(&inFlight, 1)
+				if opts.OnInFlightChange != nil {
+					opts.OnInFlightChange(int(n))
+				}
+				m.sendBatch(ctx, path, batch, opts, results)
+				n = atomic.AddInt32(&inFlight, -1)
+				if opts.OnInFlightChange != nil {
+					opts.OnInFlightChange(int(n))
+				}
+			}
+		}()
+	}
+
+	go func() {
+		m.batchRecords(ctx, in, opts, batches)
+		close(batches)
+		workers.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// batchRecords groups incoming records into recordBatch values, flushing
+// whenever the batch reaches BatchSize/BatchBytes or FlushInterval elapses
+// since the first record of the in-progress batch arrived.
+func (m *Mnubo) batchRecords(ctx context.Context, in <-chan interface{}, opts StreamOptions, out chan<- recordBatch) {
+	var batch []interface{}
+	batchBytes := 0
+	base := 0
+	seen := 0
+
+	timer := time.NewTimer(opts.FlushInterval)
+	defer timer.Stop()
+	if !timer.Stop() {
+		<-timer.C
+	}
+	timerRunning := false
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		out <- recordBatch{records: batch, base: base}
+		base = seen
+		batch = nil
+		batchBytes = 0
+		if timerRunning {
+			timer.Stop()
+			timerRunning = false
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case record, ok := <-in:
+			if !ok {
+				flush()
+				return
+			}
+			size := estimateSize(record)
+			if len(batch) > 0 && (len(batch)+1 > opts.BatchSize || batchBytes+size > opts.BatchBytes) {
+				flush()
+			}
+			batch = append(batch, record)
+			batchBytes += size
+			seen++
+			if len(batch) == 1 {
+				timer.Reset(opts.FlushInterval)
+				timerRunning = true
+			}
+			if len(batch) >= opts.BatchSize {
+				flush()
+			}
+		case <-timer.C:
+			timerRunning = false
+			flush()
+		}
+	}
+}
+
+func estimateSize(record interface{}) int {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+// sendBatch dispatches the batch's JSON payload, emitting one StreamResult
+// per record. Compression is left to doRequestOnce's own streaming gzip
+// path (forceCompression), which feeds the payload through gzipPipeReader
+// straight into the outgoing request, so the compressed body is never
+// buffered here. opts.Retry, if set, overrides the client's RetryPolicy for
+// this batch.
+func (m *Mnubo) sendBatch(ctx context.Context, path string, batch recordBatch, opts StreamOptions, results chan<- StreamResult) {
+	payload, err := json.Marshal(batch.records)
+	if err != nil {
+		for i := range batch.records {
+			results <- StreamResult{Index: batch.base + i, Err: err}
+		}
+		return
+	}
+
+	cr := ClientRequest{
+		method:           "POST",
+		path:             path,
+		contentType:      "application/json",
+		payload:          payload,
+		forceCompression: true,
+		retryPolicy:      opts.Retry,
+		hasRetryPolicy:   !opts.Retry.isZero(),
+	}
+
+	var recordResults []recordResult
+	err = m.doRequestWithAuthentication(ctx, cr, &recordResults)
+	if err != nil {
+		for i := range batch.records {
+			results <- StreamResult{Index: batch.base + i, Err: err}
+		}
+		return
+	}
+
+	for i := range batch.records {
+		var recErr error
+		if i < len(recordResults) && recordResults[i].Result != "success" {
+			recErr = fmt.Errorf("%s: %s", recordResults[i].Result, recordResults[i].Message)
+		}
+		results <- StreamResult{Index: batch.base + i, Err: recErr}
+	}
+}
@@ -0,0 +1,125 @@
+package mnubo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func collectBatches(t *testing.T, ctx context.Context, in <-chan interface{}, opts StreamOptions) []recordBatch {
+	t.Helper()
+	out := make(chan recordBatch)
+	done := make(chan struct{})
+
+	var batches []recordBatch
+	go func() {
+		for b := range out {
+			batches = append(batches, b)
+		}
+		close(done)
+	}()
+
+	(&Mnubo{}).batchRecords(ctx, in, opts.withDefaults(), out)
+	close(out)
+	<-done
+	return batches
+}
+
+func TestBatchRecordsFlushesOnBatchSize(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan interface{}, 3)
+	in <- "a"
+	in <- "b"
+	in <- "c"
+	close(in)
+
+	batches := collectBatches(t, ctx, in, StreamOptions{BatchSize: 2, FlushInterval: time.Hour})
+	if len(batches) != 2 {
+		t.Fatalf("got %d batches, want 2", len(batches))
+	}
+	if len(batches[0].records) != 2 {
+		t.Fatalf("first batch has %d records, want 2", len(batches[0].records))
+	}
+	if len(batches[1].records) != 1 {
+		t.Fatalf("second batch has %d records, want 1", len(batches[1].records))
+	}
+	if batches[1].base != 2 {
+		t.Fatalf("second batch base = %d, want 2", batches[1].base)
+	}
+}
+
+func TestBatchRecordsFlushesOnBatchBytes(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan interface{}, 2)
+	in <- "aaaaaaaaaa" // 12 bytes once JSON-quoted
+	in <- "b"
+	close(in)
+
+	batches := collectBatches(t, ctx, in, StreamOptions{BatchSize: 1000, BatchBytes: 13, FlushInterval: time.Hour})
+	if len(batches) != 2 {
+		t.Fatalf("got %d batches, want 2", len(batches))
+	}
+}
+
+func TestBatchRecordsFlushesOnFlushInterval(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan interface{})
+
+	batches := make(chan recordBatch)
+	done := make(chan struct{})
+	var got []recordBatch
+	go func() {
+		for b := range batches {
+			got = append(got, b)
+		}
+		close(done)
+	}()
+
+	go func() {
+		in <- "only"
+		time.Sleep(50 * time.Millisecond)
+		close(in)
+	}()
+
+	(&Mnubo{}).batchRecords(ctx, in, StreamOptions{BatchSize: 1000, BatchBytes: 1 << 20, FlushInterval: 10 * time.Millisecond}.withDefaults(), batches)
+	close(batches)
+	<-done
+
+	if len(got) != 1 {
+		t.Fatalf("got %d batches, want 1 flushed by the timer", len(got))
+	}
+	if len(got[0].records) != 1 {
+		t.Fatalf("batch has %d records, want 1", len(got[0].records))
+	}
+}
+
+func TestBatchRecordsFlushesRemainderOnClose(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan interface{}, 1)
+	in <- "last"
+	close(in)
+
+	batches := collectBatches(t, ctx, in, StreamOptions{BatchSize: 1000, FlushInterval: time.Hour})
+	if len(batches) != 1 {
+		t.Fatalf("got %d batches, want 1", len(batches))
+	}
+}
+
+func TestBatchRecordsStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan interface{})
+	out := make(chan recordBatch)
+
+	done := make(chan struct{})
+	go func() {
+		(&Mnubo{}).batchRecords(ctx, in, StreamOptions{}.withDefaults(), out)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("batchRecords did not return after context cancellation")
+	}
+}
@@ -0,0 +1,130 @@
+package mnubo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultTokenRefreshSkew is how far ahead of expiry a token is proactively
+// refreshed, so a request doesn't race a token that is about to expire.
+const defaultTokenRefreshSkew = 30 * time.Second
+
+// tokenCall is a single in-flight token refresh shared by every caller that
+// arrives while it's running.
+type tokenCall struct {
+	wg  sync.WaitGroup
+	val AccessToken
+	err error
+}
+
+// tokenSource coalesces concurrent token refreshes into a single HTTP call,
+// the same way golang.org/x/sync/singleflight.Group does, and guards reads
+// of the cached AccessToken so callers never race m.AccessToken.
+type tokenSource struct {
+	mu      sync.RWMutex
+	current *tokenCall
+}
+
+// do runs fn if no refresh is already in flight, otherwise waits for the
+// in-flight refresh and returns its result.
+func (ts *tokenSource) do(fn func() (AccessToken, error)) (AccessToken, error) {
+	ts.mu.Lock()
+	if c := ts.current; c != nil {
+		ts.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+
+	c := &tokenCall{}
+	c.wg.Add(1)
+	ts.current = c
+	ts.mu.Unlock()
+
+	c.val, c.err = fn()
+	c.wg.Done()
+
+	ts.mu.Lock()
+	ts.current = nil
+	ts.mu.Unlock()
+
+	return c.val, c.err
+}
+
+func (m *Mnubo) tokenRefreshSkew() time.Duration {
+	if m.TokenRefreshSkew > 0 {
+		return m.TokenRefreshSkew
+	}
+	return defaultTokenRefreshSkew
+}
+
+// needsRefresh reports whether the cached token is missing, expired, or
+// within the refresh skew of expiring.
+func (m *Mnubo) needsRefresh() bool {
+	m.tokens.mu.RLock()
+	defer m.tokens.mu.RUnlock()
+	return m.AccessToken.Value == "" || time.Now().Add(m.tokenRefreshSkew()).After(m.AccessToken.ExpiresAt)
+}
+
+func (m *Mnubo) currentAccessToken() AccessToken {
+	m.tokens.mu.RLock()
+	defer m.tokens.mu.RUnlock()
+	return m.AccessToken
+}
+
+func (m *Mnubo) setAccessToken(at AccessToken) {
+	m.tokens.mu.Lock()
+	m.AccessToken = at
+	m.tokens.mu.Unlock()
+}
+
+// refreshAccessToken proactively refreshes the access token when it's
+// missing, expired, or close to expiring, coalescing concurrent callers into
+// a single /oauth/token request.
+func (m *Mnubo) refreshAccessToken(ctx context.Context) (AccessToken, error) {
+	if !m.needsRefresh() {
+		return m.currentAccessToken(), nil
+	}
+	return m.tokens.do(func() (AccessToken, error) {
+		if !m.needsRefresh() {
+			return m.currentAccessToken(), nil
+		}
+		return m.GetAccessTokenContext(ctx)
+	})
+}
+
+// forceRefreshAccessToken unconditionally refreshes the access token,
+// skipping the needsRefresh check, while still coalescing concurrent callers
+// into a single /oauth/token request via tokens.do. Used when the server has
+// rejected the cached token outright (e.g. a 401) and a stale "not expired
+// yet" reading can't be trusted.
+func (m *Mnubo) forceRefreshAccessToken(ctx context.Context) (AccessToken, error) {
+	return m.tokens.do(func() (AccessToken, error) {
+		return m.GetAccessTokenContext(ctx)
+	})
+}
+
+// StartTokenRefresher launches a background goroutine that proactively
+// refreshes the access token as it approaches expiry, so request-path
+// callers rarely block on a token fetch. It stops when ctx is done or the
+// returned stop function is called.
+func (m *Mnubo) StartTokenRefresher(ctx context.Context) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(m.tokenRefreshSkew() / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if m.needsRefresh() {
+					m.refreshAccessToken(ctx)
+				}
+			}
+		}
+	}()
+
+	return cancel
+}
@@ -0,0 +1,86 @@
+package mnubo
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenSourceDoCoalescesConcurrentCalls(t *testing.T) {
+	var ts tokenSource
+	var calls int32
+
+	release := make(chan struct{})
+
+	fn := func() (AccessToken, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return AccessToken{Value: "tok"}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]AccessToken, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			at, err := ts.do(fn)
+			if err != nil {
+				t.Errorf("do() returned error: %v", err)
+			}
+			results[i] = at
+		}(i)
+	}
+
+	// Give all 5 goroutines a chance to reach ts.do() and join the in-flight
+	// call before we let it complete; otherwise a late goroutine could arrive
+	// after the winner has already reset tokenSource.current and trigger a
+	// second, uncoalesced call.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("fn called %d times, want 1 (coalesced)", got)
+	}
+	for i, at := range results {
+		if at.Value != "tok" {
+			t.Fatalf("results[%d].Value = %q, want %q", i, at.Value, "tok")
+		}
+	}
+}
+
+func TestTokenSourceDoRunsAgainAfterCompletion(t *testing.T) {
+	var ts tokenSource
+	var calls int32
+
+	fn := func() (AccessToken, error) {
+		atomic.AddInt32(&calls, 1)
+		return AccessToken{}, nil
+	}
+
+	if _, err := ts.do(fn); err != nil {
+		t.Fatalf("first do() returned error: %v", err)
+	}
+	if _, err := ts.do(fn); err != nil {
+		t.Fatalf("second do() returned error: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("fn called %d times, want 2 (sequential, not coalesced)", got)
+	}
+}
+
+func TestTokenSourceDoPropagatesError(t *testing.T) {
+	var ts tokenSource
+	wantErr := errors.New("refresh failed")
+
+	_, err := ts.do(func() (AccessToken, error) {
+		return AccessToken{}, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("do() error = %v, want %v", err, wantErr)
+	}
+}